@@ -0,0 +1,71 @@
+// Package config defines the runtime configuration for cursor2api-go,
+// typically loaded from a YAML/JSON file or environment into a Config and
+// passed to services.NewCursorService.
+package config
+
+// Config holds every knob CursorService and its subsystems read at
+// startup. Fields are grouped by the subsystem that owns them; see each
+// field's comment for what introduced it.
+type Config struct {
+	// Timeout is the HTTP client timeout, in seconds, used for both the
+	// challenge-script fetch and the chat completion request.
+	Timeout int
+	// SystemPromptInject is prepended to every conversation sent to Cursor.
+	SystemPromptInject string
+	// ScriptURL is where the Cursor challenge script is fetched from. An
+	// empty value runs fetchXIsHuman in fallback mode (empty script body).
+	ScriptURL string
+	// MaxInputLength is the token budget truncateMessages enforces across
+	// a conversation, model context size permitting.
+	MaxInputLength int
+	// FP controls the browser fingerprint presented alongside the
+	// challenge-script fetch and chat request.
+	FP FingerprintConfig
+
+	// JSRuntimeBackend selects the JSRuntime implementation used to
+	// evaluate the challenge script: "goja" (default) or "quickjs" (only
+	// available in binaries built with `-tags qjs`).
+	JSRuntimeBackend string
+	// JSMemoryLimitBytes bounds the heap of a single JS isolate. Zero means
+	// no limit. Only enforced by backends that support it (currently just
+	// quickjs; see GojaRuntime's doc comment).
+	JSMemoryLimitBytes uint64
+	// JSPoolSize caps how many warm isolates utils.IsolatePool keeps
+	// around. Zero uses the pool's own default.
+	JSPoolSize int
+	// JSEvalTimeoutMs is the hard wall-clock limit, in milliseconds, for a
+	// single isolate Eval call. Zero uses the pool's own default.
+	JSEvalTimeoutMs int
+
+	// AccountsFile, if set, loads a JSON or YAML file of Cursor credentials
+	// and routes requests through an accounts.Pool instead of the single
+	// shared client. Empty disables account rotation.
+	AccountsFile string
+	// AccountsStateFile, if set, persists each account's cooldown state to
+	// this path so a restart doesn't forget which accounts were cooling
+	// down. Empty disables persistence. Only meaningful with AccountsFile
+	// set.
+	AccountsStateFile string
+
+	// TokenProviderFallback selects the provider CursorService fails over
+	// to once the primary (jsvm) provider has failed repeatedly. Currently
+	// only "playwright" is recognized; empty disables failover.
+	TokenProviderFallback string
+	// TokenProviderFailoverThreshold is how many consecutive jsvm failures
+	// trigger a switch to the fallback provider. Zero uses the provider's
+	// own default.
+	TokenProviderFailoverThreshold int
+
+	// ReservedCompletionTokens is subtracted from MaxInputLength before
+	// truncateMessages budgets the conversation, leaving headroom for the
+	// model's reply.
+	ReservedCompletionTokens int
+}
+
+// FingerprintConfig is the browser identity spoofed for requests that
+// interact with Cursor's challenge script.
+type FingerprintConfig struct {
+	UserAgent               string
+	UNMASKED_VENDOR_WEBGL   string
+	UNMASKED_RENDERER_WEBGL string
+}
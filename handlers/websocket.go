@@ -0,0 +1,132 @@
+// Package handlers exposes CursorService over HTTP transports.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"cursor2api-go/models"
+	"cursor2api-go/services"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPingPeriod = 30 * time.Second
+	wsPongWait   = wsPingPeriod + 10*time.Second
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsClientMessage is the shape of incoming frames after the first one.
+// Today the only supported type is "cancel"; anything else is ignored.
+type wsClientMessage struct {
+	Type string `json:"type"`
+}
+
+// ChatCompletionWebSocketHandler upgrades to a WebSocket on
+// /v1/chat/completions/ws, reads the ChatCompletionRequest as the first
+// frame's JSON body, and streams the same delta events the SSE handler
+// emits, one per message, until the stream ends or the client cancels.
+func ChatCompletionWebSocketHandler(cursorService *services.CursorService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logrus.WithError(err).Warn("failed to upgrade websocket connection")
+			return
+		}
+		defer conn.Close()
+
+		var request models.ChatCompletionRequest
+		if err := conn.ReadJSON(&request); err != nil {
+			writeWSError(conn, "invalid_request", err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		output, err := cursorService.ChatCompletion(ctx, &request)
+		if err != nil {
+			writeWSError(conn, "chat_completion_failed", err.Error())
+			return
+		}
+
+		go readControlFrames(conn, cancel)
+		streamToWebSocket(conn, output)
+	}
+}
+
+// readControlFrames pumps incoming frames so pong control frames are
+// processed and a client-initiated {"type":"cancel"} message cancels the
+// underlying ChatCompletion context. It returns after a cancel message, or
+// once the connection is closed from either side.
+func readControlFrames(conn *websocket.Conn, cancel context.CancelFunc) {
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		return nil
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg wsClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		if msg.Type == "cancel" {
+			cancel()
+			return
+		}
+	}
+}
+
+// streamToWebSocket relays every event off output as a JSON text frame
+// and keeps the connection alive with periodic pings until output closes
+// or the connection breaks.
+func streamToWebSocket(conn *websocket.Conn, output <-chan interface{}) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-output:
+			if !ok {
+				conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				logrus.WithError(err).Warn("failed to write websocket frame")
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeWSError(conn *websocket.Conn, code, message string) {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	conn.WriteJSON(map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,35 @@
+// Package limiter rate-limits and quota-enforces calls into
+// CursorService.ChatCompletion, keyed by API key, remote IP and model
+// name, sitting in front of the existing 403/retry logic rather than
+// inside it.
+package limiter
+
+import "time"
+
+// Rule is the limit applied to a single key (an API key, an IP, or a
+// model name, depending on which Keyer produced it).
+type Rule struct {
+	RPS            float64       // sustained requests per second
+	Burst          int           // token bucket capacity
+	DailyQuota     int           // 0 means unlimited
+	MaxConcurrency int           // 0 means unlimited
+	ResetInterval  time.Duration // daily quota window, defaults to 24h
+}
+
+func (r Rule) withDefaults() Rule {
+	if r.ResetInterval <= 0 {
+		r.ResetInterval = 24 * time.Hour
+	}
+	if r.Burst <= 0 {
+		r.Burst = 1
+	}
+	return r
+}
+
+// Config groups the rules applied per dimension. A zero-value Rule for a
+// dimension disables limiting on that dimension.
+type Config struct {
+	PerAPIKey Rule
+	PerIP     Rule
+	PerModel  Rule
+}
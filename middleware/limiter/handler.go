@@ -0,0 +1,28 @@
+package limiter
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// AdminHandler serves GET /admin/limiter (counter snapshot) and
+// DELETE /admin/limiter?key=... (reset a single key's counters).
+func AdminHandler(store Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(store.Counters())
+		case http.MethodDelete:
+			key := r.URL.Query().Get("key")
+			if key == "" {
+				http.Error(w, "missing key parameter", http.StatusBadRequest)
+				return
+			}
+			store.Reset(key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package limiter
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// keyFunc extracts the rate-limit key for one dimension from a request.
+// The model dimension has none, since the model name lives in the JSON
+// body rather than a header; see AcquireModelSlot.
+type keyFunc func(r *http.Request) string
+
+func apiKeyOf(r *http.Request) string {
+	if key := r.Header.Get("Authorization"); key != "" {
+		return key
+	}
+	return r.Header.Get("X-Api-Key")
+}
+
+func remoteIPOf(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// Middleware wraps next with token-bucket and daily-quota enforcement
+// keyed by API key and remote IP. The model dimension is applied
+// separately via AcquireModelSlot, since the model name isn't known until
+// the request body has been parsed.
+func Middleware(cfg Config, store Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			dims := []struct {
+				rule Rule
+				key  string
+			}{
+				{cfg.PerAPIKey, "apikey:" + apiKeyOf(r)},
+				{cfg.PerIP, "ip:" + remoteIPOf(r)},
+			}
+
+			for _, dim := range dims {
+				if dim.rule.RPS <= 0 && dim.rule.DailyQuota <= 0 {
+					continue
+				}
+				decision, err := store.Allow(r.Context(), dim.key, dim.rule)
+				if err != nil {
+					writeJSONError(w, http.StatusInternalServerError, "rate_limiter_error", err.Error(), 0)
+					return
+				}
+				if !decision.Allowed {
+					writeJSONError(w, http.StatusTooManyRequests, "rate_limit_exceeded", "too many requests", decision.RetryAfter.Seconds())
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AcquireModelSlot enforces the per-model concurrency cap. Handlers call
+// it once the request body has been decoded and the model name is known,
+// and must call the returned release function when the request finishes.
+// Only PerModel.MaxConcurrency is enforced for the model dimension;
+// PerModel's RPS, Burst and DailyQuota are not applied by this package.
+func AcquireModelSlot(cfg Config, store Store, r *http.Request, model string) (func(), error) {
+	if cfg.PerModel.MaxConcurrency <= 0 {
+		return func() {}, nil
+	}
+	return store.Acquire(r.Context(), "model:"+model, cfg.PerModel)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, code, message string, retryAfterSeconds float64) {
+	if retryAfterSeconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfterSeconds+0.5)))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":        code,
+			"message":     message,
+			"retry_after": retryAfterSeconds,
+		},
+	})
+}
@@ -0,0 +1,153 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Decision is the outcome of a limit check for one key.
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// Store tracks rate-limit and quota state per key. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	// Allow checks and, if allowed, consumes one unit of the bucket and
+	// the daily quota for key under rule.
+	Allow(ctx context.Context, key string, rule Rule) (Decision, error)
+	// Acquire/Release bound in-flight concurrency for key under rule.
+	// Acquire blocks until a slot is free or ctx is done.
+	Acquire(ctx context.Context, key string, rule Rule) (func(), error)
+	// Counters returns a point-in-time snapshot for the admin endpoint.
+	Counters() map[string]Counter
+	// Reset clears all state for key.
+	Reset(key string)
+}
+
+// Counter is what the admin endpoint exposes for a single key.
+type Counter struct {
+	UsedToday int       `json:"used_today"`
+	ResetAt   time.Time `json:"reset_at"`
+	InFlight  int       `json:"in_flight"`
+}
+
+type bucketState struct {
+	limiter   *rate.Limiter
+	sem       chan struct{}
+	usedToday int
+	resetAt   time.Time
+}
+
+// MemoryStore is the default, single-process Store backed by
+// golang.org/x/time/rate token buckets plus an in-memory daily counter.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucketState
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucketState)}
+}
+
+func (s *MemoryStore) get(key string, rule Rule) *bucketState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucketState{
+			limiter: rate.NewLimiter(rate.Limit(rule.RPS), rule.Burst),
+			resetAt: time.Now().Add(rule.ResetInterval),
+		}
+		if rule.MaxConcurrency > 0 {
+			b.sem = make(chan struct{}, rule.MaxConcurrency)
+		}
+		s.buckets[key] = b
+	}
+	if time.Now().After(b.resetAt) {
+		b.usedToday = 0
+		b.resetAt = time.Now().Add(rule.ResetInterval)
+	}
+	return b
+}
+
+// Allow implements Store.
+func (s *MemoryStore) Allow(ctx context.Context, key string, rule Rule) (Decision, error) {
+	rule = rule.withDefaults()
+	b := s.get(key, rule)
+
+	s.mu.Lock()
+	if rule.DailyQuota > 0 && b.usedToday >= rule.DailyQuota {
+		retryAfter := time.Until(b.resetAt)
+		s.mu.Unlock()
+		return Decision{Allowed: false, RetryAfter: retryAfter}, nil
+	}
+	s.mu.Unlock()
+
+	if rule.RPS <= 0 {
+		s.mu.Lock()
+		b.usedToday++
+		s.mu.Unlock()
+		return Decision{Allowed: true}, nil
+	}
+
+	reservation := b.limiter.Reserve()
+	if !reservation.OK() {
+		return Decision{Allowed: false, RetryAfter: time.Second}, nil
+	}
+	delay := reservation.Delay()
+	if delay > 0 {
+		reservation.Cancel()
+		return Decision{Allowed: false, RetryAfter: delay}, nil
+	}
+
+	s.mu.Lock()
+	b.usedToday++
+	s.mu.Unlock()
+	return Decision{Allowed: true}, nil
+}
+
+// Acquire implements Store.
+func (s *MemoryStore) Acquire(ctx context.Context, key string, rule Rule) (func(), error) {
+	rule = rule.withDefaults()
+	if rule.MaxConcurrency <= 0 {
+		return func() {}, nil
+	}
+	b := s.get(key, rule)
+
+	select {
+	case b.sem <- struct{}{}:
+		return func() { <-b.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Counters implements Store.
+func (s *MemoryStore) Counters() map[string]Counter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]Counter, len(s.buckets))
+	for key, b := range s.buckets {
+		inFlight := 0
+		if b.sem != nil {
+			inFlight = len(b.sem)
+		}
+		out[key] = Counter{UsedToday: b.usedToday, ResetAt: b.resetAt, InFlight: inFlight}
+	}
+	return out
+}
+
+// Reset implements Store.
+func (s *MemoryStore) Reset(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.buckets, key)
+}
@@ -0,0 +1,91 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMemoryStore_DailyQuota(t *testing.T) {
+	store := NewMemoryStore()
+	rule := Rule{RPS: 1000, Burst: 1000, DailyQuota: 2}
+
+	for i := 0; i < 2; i++ {
+		decision, err := store.Allow(context.Background(), "k", rule)
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !decision.Allowed {
+			t.Fatalf("expected request %d to be allowed", i)
+		}
+	}
+
+	decision, err := store.Allow(context.Background(), "k", rule)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if decision.Allowed {
+		t.Fatal("expected third request to exceed the daily quota")
+	}
+}
+
+func TestMemoryStore_DailyQuotaWithoutRPS(t *testing.T) {
+	store := NewMemoryStore()
+	rule := Rule{DailyQuota: 2} // quota-only: no RPS smoothing configured
+
+	for i := 0; i < 2; i++ {
+		decision, err := store.Allow(context.Background(), "k", rule)
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !decision.Allowed {
+			t.Fatalf("expected request %d to be allowed", i)
+		}
+	}
+
+	decision, err := store.Allow(context.Background(), "k", rule)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if decision.Allowed {
+		t.Fatal("expected third request to exceed the daily quota even with RPS unset")
+	}
+}
+
+func TestMemoryStore_Concurrency(t *testing.T) {
+	store := NewMemoryStore()
+	rule := Rule{MaxConcurrency: 1}
+
+	release, err := store.Acquire(context.Background(), "k", rule)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	if _, err := store.Acquire(ctx, "k", rule); err == nil {
+		t.Fatal("expected second Acquire to block past the concurrency cap")
+	}
+
+	release()
+	if _, err := store.Acquire(context.Background(), "k", rule); err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+}
+
+func TestMemoryStore_Reset(t *testing.T) {
+	store := NewMemoryStore()
+	rule := Rule{RPS: 1000, Burst: 1000, DailyQuota: 1}
+
+	if _, err := store.Allow(context.Background(), "k", rule); err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	store.Reset("k")
+
+	decision, err := store.Allow(context.Background(), "k", rule)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if !decision.Allowed {
+		t.Fatal("expected quota to be cleared after Reset")
+	}
+}
@@ -0,0 +1,9 @@
+package models
+
+// WithStringContent returns a copy of m with its content replaced by the
+// given flattened string. Used by middle-out truncation when a single
+// message alone exceeds the token budget and can't simply be dropped.
+func (m Message) WithStringContent(content string) Message {
+	m.Content = content
+	return m
+}
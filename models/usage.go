@@ -0,0 +1,11 @@
+package models
+
+// Usage reports estimated token usage for a chat completion. It is
+// pushed onto ChatCompletion's event channel as the last event once the
+// stream finishes without error, alongside the delta events the SSE/WebSocket
+// transports already forward.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
@@ -0,0 +1,149 @@
+// Package accounts manages a pool of Cursor credentials so CursorService
+// can spread requests across multiple sessions instead of sharing one
+// cookie jar (and one rate limit) for every caller.
+package accounts
+
+import (
+	"net/http/cookiejar"
+	"sync"
+	"time"
+
+	"cursor2api-go/utils"
+
+	"github.com/imroc/req/v3"
+)
+
+// Credential is one set of Cursor login material loaded from config or an
+// accounts file.
+type Credential struct {
+	Name    string            `json:"name" yaml:"name"`
+	Cookies map[string]string `json:"cookies" yaml:"cookies"`
+}
+
+// Account wraps a Credential with everything CursorService needs to make
+// requests as that identity: its own client, cookie jar, fingerprint and
+// script cache, so one account's state never leaks into another's.
+type Account struct {
+	Credential Credential
+	Client     *req.Client
+	Headers    *utils.HeaderGenerator
+
+	mu              sync.Mutex
+	scriptCache     string
+	scriptCacheTime time.Time
+
+	cooldownUntil time.Time
+	failureCount  int
+}
+
+// newAccount builds an Account with its own client and cookie jar so
+// requests made on behalf of different accounts never share Cursor
+// session state.
+func newAccount(cred Credential, timeout time.Duration) (*Account, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := req.C()
+	client.SetTimeout(timeout)
+	client.ImpersonateChrome()
+	client.SetCookieJar(jar)
+
+	return &Account{
+		Credential: cred,
+		Client:     client,
+		Headers:    utils.NewHeaderGenerator(),
+	}, nil
+}
+
+// ScriptCache returns the cached challenge script body for this account,
+// if it's still fresh, mirroring CursorService's single-account cache.
+func (a *Account) ScriptCache(maxAge time.Duration) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.scriptCache == "" || time.Since(a.scriptCacheTime) >= maxAge {
+		return "", false
+	}
+	return a.scriptCache, true
+}
+
+// SetScriptCache updates the cached challenge script body for this account.
+func (a *Account) SetScriptCache(body string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scriptCache = body
+	a.scriptCacheTime = time.Now()
+}
+
+// ClearScriptCache drops the cached script body, e.g. after a failed eval.
+func (a *Account) ClearScriptCache() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scriptCache = ""
+	a.scriptCacheTime = time.Time{}
+}
+
+// healthy reports whether the account is past its cooldown window.
+func (a *Account) healthy() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return time.Now().After(a.cooldownUntil)
+}
+
+// coolDown puts the account to sleep with exponential backoff based on its
+// consecutive failure count, e.g. after a 403/Cloudflare response.
+func (a *Account) coolDown(base time.Duration, max time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failureCount++
+	backoff := base << uint(a.failureCount-1)
+	if backoff > max || backoff <= 0 {
+		backoff = max
+	}
+	a.cooldownUntil = time.Now().Add(backoff)
+}
+
+// markHealthy resets the failure streak after a successful request.
+func (a *Account) markHealthy() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failureCount = 0
+	a.cooldownUntil = time.Time{}
+}
+
+// cooldownState returns the raw cooldown fields for persistence.
+func (a *Account) cooldownState() (cooldownUntil time.Time, failureCount int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cooldownUntil, a.failureCount
+}
+
+// restoreCooldownState applies cooldown fields loaded from disk, e.g. at
+// startup after a restart.
+func (a *Account) restoreCooldownState(cooldownUntil time.Time, failureCount int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cooldownUntil = cooldownUntil
+	a.failureCount = failureCount
+}
+
+// Status is a snapshot of an account's health, used by the /admin/accounts
+// endpoint.
+type Status struct {
+	Name          string    `json:"name"`
+	Healthy       bool      `json:"healthy"`
+	FailureCount  int       `json:"failure_count"`
+	CooldownUntil time.Time `json:"cooldown_until,omitempty"`
+}
+
+func (a *Account) status() Status {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return Status{
+		Name:          a.Credential.Name,
+		Healthy:       time.Now().After(a.cooldownUntil),
+		FailureCount:  a.failureCount,
+		CooldownUntil: a.cooldownUntil,
+	}
+}
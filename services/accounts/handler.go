@@ -0,0 +1,20 @@
+package accounts
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// StatusHandler serves GET /admin/accounts with a JSON snapshot of every
+// account's health, for operators tracking down why throughput dropped.
+func StatusHandler(pool *Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pool.Statuses())
+	}
+}
@@ -0,0 +1,140 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PoolConfig controls cooldown behaviour shared by every account in the
+// pool.
+type PoolConfig struct {
+	CooldownBase  time.Duration
+	CooldownMax   time.Duration
+	ClientTimeout time.Duration
+	// StatePath, if set, persists every account's cooldown state to disk
+	// as JSON so a restart doesn't forget which accounts were cooling
+	// down. Empty disables persistence.
+	StatePath string
+}
+
+func (c PoolConfig) withDefaults() PoolConfig {
+	if c.CooldownBase <= 0 {
+		c.CooldownBase = 2 * time.Second
+	}
+	if c.CooldownMax <= 0 {
+		c.CooldownMax = 5 * time.Minute
+	}
+	if c.ClientTimeout <= 0 {
+		c.ClientTimeout = 30 * time.Second
+	}
+	return c
+}
+
+// Pool round-robins requests across a set of Cursor accounts, routing
+// around ones that are cooling down after a 403 or Cloudflare challenge.
+type Pool struct {
+	cfg PoolConfig
+
+	mu       sync.Mutex
+	accounts []*Account
+	next     int
+}
+
+// NewPool creates a Pool from the given credentials.
+func NewPool(creds []Credential, cfg PoolConfig) (*Pool, error) {
+	if len(creds) == 0 {
+		return nil, fmt.Errorf("accounts: at least one credential is required")
+	}
+	cfg = cfg.withDefaults()
+
+	accounts := make([]*Account, 0, len(creds))
+	for _, cred := range creds {
+		acc, err := newAccount(cred, cfg.ClientTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("accounts: failed to initialize %q: %w", cred.Name, err)
+		}
+		accounts = append(accounts, acc)
+	}
+
+	pool := &Pool{cfg: cfg, accounts: accounts}
+	if cfg.StatePath != "" {
+		if err := pool.loadState(); err != nil {
+			return nil, err
+		}
+	}
+
+	return pool, nil
+}
+
+// LoadCredentialsFile reads a JSON or YAML file of credentials, selecting
+// the decoder by extension.
+func LoadCredentialsFile(path string) ([]Credential, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("accounts: failed to read %s: %w", path, err)
+	}
+
+	var creds []Credential
+	if isJSONFile(path) {
+		err = json.Unmarshal(data, &creds)
+	} else {
+		err = yaml.Unmarshal(data, &creds)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("accounts: failed to parse %s: %w", path, err)
+	}
+	return creds, nil
+}
+
+func isJSONFile(path string) bool {
+	return len(path) >= 5 && path[len(path)-5:] == ".json"
+}
+
+// Acquire returns the next healthy account in round-robin order along with
+// a release function the caller must invoke once it knows whether the
+// request against Cursor succeeded.
+func (p *Pool) Acquire(ctx context.Context) (*Account, func(success bool), error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := len(p.accounts)
+	for i := 0; i < n; i++ {
+		idx := (p.next + i) % n
+		acc := p.accounts[idx]
+		if !acc.healthy() {
+			continue
+		}
+		p.next = (idx + 1) % n
+
+		release := func(success bool) {
+			if success {
+				acc.markHealthy()
+			} else {
+				acc.coolDown(p.cfg.CooldownBase, p.cfg.CooldownMax)
+			}
+			p.persistState()
+		}
+		return acc, release, nil
+	}
+
+	return nil, nil, fmt.Errorf("accounts: no healthy account available (%d cooling down)", n)
+}
+
+// Statuses returns a point-in-time snapshot of every account, for the
+// /admin/accounts endpoint.
+func (p *Pool) Statuses() []Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	statuses := make([]Status, 0, len(p.accounts))
+	for _, acc := range p.accounts {
+		statuses = append(statuses, acc.status())
+	}
+	return statuses
+}
@@ -0,0 +1,77 @@
+package accounts
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func testCreds(names ...string) []Credential {
+	creds := make([]Credential, len(names))
+	for i, name := range names {
+		creds[i] = Credential{Name: name}
+	}
+	return creds
+}
+
+func TestPool_RoundRobin(t *testing.T) {
+	pool, err := NewPool(testCreds("a", "b"), PoolConfig{})
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		acc, release, err := pool.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+		seen[acc.Credential.Name] = true
+		release(true)
+	}
+
+	if !seen["a"] || !seen["b"] {
+		t.Fatalf("expected both accounts to be used, got %v", seen)
+	}
+}
+
+func TestPool_SkipsCoolingDownAccount(t *testing.T) {
+	pool, err := NewPool(testCreds("a", "b"), PoolConfig{CooldownBase: time.Minute, CooldownMax: time.Minute})
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+
+	acc, release, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	first := acc.Credential.Name
+	release(false) // simulate a 403, account a cools down
+
+	acc2, release2, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer release2(true)
+
+	if acc2.Credential.Name == first {
+		t.Fatalf("expected pool to skip cooling-down account %q, got it again", first)
+	}
+}
+
+func TestPool_AllAccountsCoolingDown(t *testing.T) {
+	pool, err := NewPool(testCreds("a"), PoolConfig{CooldownBase: time.Minute, CooldownMax: time.Minute})
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+
+	_, release, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	release(false)
+
+	if _, _, err := pool.Acquire(context.Background()); err == nil {
+		t.Fatal("expected error when every account is cooling down")
+	}
+}
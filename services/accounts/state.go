@@ -0,0 +1,74 @@
+package accounts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// persistedAccountState is one account's cooldown state as written to
+// PoolConfig.StatePath, keyed by Credential.Name in the file on disk.
+type persistedAccountState struct {
+	CooldownUntil time.Time `json:"cooldown_until"`
+	FailureCount  int       `json:"failure_count"`
+}
+
+// loadState reads PoolConfig.StatePath, if it exists, and restores each
+// matching account's cooldown state. A missing file is not an error -
+// it just means no account has cooled down since this was last started.
+func (p *Pool) loadState() error {
+	data, err := os.ReadFile(p.cfg.StatePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("accounts: failed to read state file %s: %w", p.cfg.StatePath, err)
+	}
+
+	var state map[string]persistedAccountState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("accounts: failed to parse state file %s: %w", p.cfg.StatePath, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, acc := range p.accounts {
+		if saved, ok := state[acc.Credential.Name]; ok {
+			acc.restoreCooldownState(saved.CooldownUntil, saved.FailureCount)
+		}
+	}
+	return nil
+}
+
+// persistState writes every account's current cooldown state to
+// PoolConfig.StatePath. It's a no-op when persistence isn't configured,
+// and failures are logged rather than propagated since a missed write
+// shouldn't fail the request that triggered it.
+func (p *Pool) persistState() {
+	if p.cfg.StatePath == "" {
+		return
+	}
+
+	p.mu.Lock()
+	state := make(map[string]persistedAccountState, len(p.accounts))
+	for _, acc := range p.accounts {
+		cooldownUntil, failureCount := acc.cooldownState()
+		state[acc.Credential.Name] = persistedAccountState{
+			CooldownUntil: cooldownUntil,
+			FailureCount:  failureCount,
+		}
+	}
+	p.mu.Unlock()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		logrus.WithError(err).Warn("accounts: failed to marshal cooldown state")
+		return
+	}
+	if err := os.WriteFile(p.cfg.StatePath, data, 0o600); err != nil {
+		logrus.WithError(err).Warnf("accounts: failed to persist cooldown state to %s", p.cfg.StatePath)
+	}
+}
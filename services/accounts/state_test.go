@@ -0,0 +1,54 @@
+package accounts
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPool_PersistsAndRestoresCooldownState(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "accounts_state.json")
+
+	pool, err := NewPool(testCreds("a", "b"), PoolConfig{
+		CooldownBase: time.Minute,
+		CooldownMax:  time.Minute,
+		StatePath:    statePath,
+	})
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+
+	acc, release, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	cooled := acc.Credential.Name
+	release(false) // simulate a 403, which should persist the cooldown
+
+	restored, err := NewPool(testCreds("a", "b"), PoolConfig{
+		CooldownBase: time.Minute,
+		CooldownMax:  time.Minute,
+		StatePath:    statePath,
+	})
+	if err != nil {
+		t.Fatalf("NewPool (restore): %v", err)
+	}
+
+	for _, status := range restored.Statuses() {
+		if status.Name == cooled && status.Healthy {
+			t.Fatalf("expected account %q to still be cooling down after restart", cooled)
+		}
+		if status.Name != cooled && !status.Healthy {
+			t.Fatalf("expected account %q to be healthy, it was never cooled down", status.Name)
+		}
+	}
+}
+
+func TestPool_LoadStateMissingFileIsNotAnError(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "does-not-exist.json")
+
+	if _, err := NewPool(testCreds("a"), PoolConfig{StatePath: statePath}); err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"cursor2api-go/config"
 	"cursor2api-go/middleware"
 	"cursor2api-go/models"
+	"cursor2api-go/services/accounts"
+	"cursor2api-go/services/playwrighttoken"
 	"cursor2api-go/utils"
 	"encoding/json"
 	"errors"
@@ -34,6 +36,23 @@ type CursorService struct {
 	scriptCacheTime time.Time
 	scriptMutex     sync.RWMutex
 	headerGenerator *utils.HeaderGenerator
+	jsPool          *utils.IsolatePool
+	accountPool     *accounts.Pool
+	tokenProvider   *failoverTokenProvider
+}
+
+// newJSRuntime builds the configured JSRuntime backend. Unknown or empty
+// backends fall back to goja so existing configs keep working untouched.
+// The "quickjs" case is resolved by newQuickJSBackend, which is swapped
+// per build tag so a default (non-"qjs") build never references the
+// cgo-only utils.NewQuickJSRuntime symbol.
+func newJSRuntime(cfg *config.Config) utils.JSRuntime {
+	switch cfg.JSRuntimeBackend {
+	case "quickjs":
+		return newQuickJSBackend(cfg)
+	default:
+		return utils.NewGojaRuntime(cfg.JSMemoryLimitBytes)
+	}
 }
 
 // NewCursorService creates a new service instance.
@@ -60,23 +79,62 @@ func NewCursorService(cfg *config.Config) *CursorService {
 		client.SetCookieJar(jar)
 	}
 
-	return &CursorService{
+	jsPool := utils.NewIsolatePool(newJSRuntime(cfg), utils.IsolatePoolConfig{
+		MaxIsolates: cfg.JSPoolSize,
+		EvalTimeout: time.Duration(cfg.JSEvalTimeoutMs) * time.Millisecond,
+	})
+
+	var accountPool *accounts.Pool
+	if cfg.AccountsFile != "" {
+		creds, err := accounts.LoadCredentialsFile(cfg.AccountsFile)
+		if err != nil {
+			logrus.Fatalf("failed to load accounts file: %v", err)
+		}
+		accountPool, err = accounts.NewPool(creds, accounts.PoolConfig{
+			ClientTimeout: time.Duration(cfg.Timeout) * time.Second,
+			StatePath:     cfg.AccountsStateFile,
+		})
+		if err != nil {
+			logrus.Fatalf("failed to initialize account pool: %v", err)
+		}
+	}
+
+	service := &CursorService{
 		config:          cfg,
 		client:          client,
 		mainJS:          string(mainJS),
 		envJS:           string(envJS),
 		headerGenerator: utils.NewHeaderGenerator(),
+		jsPool:          jsPool,
+		accountPool:     accountPool,
+	}
+
+	var fallback TokenProvider
+	if cfg.TokenProviderFallback == "playwright" {
+		provider, err := playwrighttoken.New(playwrighttoken.Config{
+			UserAgent:             cfg.FP.UserAgent,
+			UnmaskedVendorWebGL:   cfg.FP.UNMASKED_VENDOR_WEBGL,
+			UnmaskedRendererWebGL: cfg.FP.UNMASKED_RENDERER_WEBGL,
+		})
+		if err != nil {
+			logrus.WithError(err).Warn("failed to start playwright token provider, falling back to jsvm only")
+		} else {
+			fallback = provider
+		}
 	}
+	service.tokenProvider = newFailoverTokenProvider(&jsvmTokenProvider{svc: service}, fallback, cfg.TokenProviderFailoverThreshold)
+
+	return service
 }
 
 // ChatCompletion creates a chat completion stream for the given request.
 func (s *CursorService) ChatCompletion(ctx context.Context, request *models.ChatCompletionRequest) (<-chan interface{}, error) {
-	truncatedMessages := s.truncateMessages(request.Messages)
-	cursorMessages := models.ToCursorMessages(truncatedMessages, s.config.SystemPromptInject)
-
 	// 转换模型名称为 Cursor API 格式
 	cursorModel := s.convertModelName(request.Model)
-	
+
+	truncatedMessages, promptTokens := s.truncateMessages(request.Messages, request.Model)
+	cursorMessages := models.ToCursorMessages(truncatedMessages, s.config.SystemPromptInject)
+
 	payload := models.CursorRequest{
 		Context:  []interface{}{},
 		Model:    cursorModel,
@@ -92,9 +150,33 @@ func (s *CursorService) ChatCompletion(ctx context.Context, request *models.Chat
 
 	// 尝试最多2次
 	maxRetries := 2
+	if s.accountPool != nil {
+		// 使用账号池时最多尝试3次，每次换一个健康账号
+		maxRetries = 3
+	}
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		xIsHuman, err := s.fetchXIsHuman(ctx)
+		var (
+			client         = s.client
+			headerGen      = s.headerGenerator
+			acc            *accounts.Account
+			releaseAccount func(success bool)
+		)
+		if s.accountPool != nil {
+			var release func(success bool)
+			acc, release, err = s.accountPool.Acquire(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("cursor request failed: %w", err)
+			}
+			client = acc.Client
+			headerGen = acc.Headers
+			releaseAccount = release
+		}
+
+		xIsHuman, err := s.tokenProvider.Token(ctx, acc)
 		if err != nil {
+			if releaseAccount != nil {
+				releaseAccount(false)
+			}
 			if attempt < maxRetries {
 				logrus.WithError(err).Warnf("Failed to fetch x-is-human token (attempt %d/%d), retrying...", attempt, maxRetries)
 				time.Sleep(time.Second * time.Duration(attempt)) // 指数退避
@@ -104,7 +186,7 @@ func (s *CursorService) ChatCompletion(ctx context.Context, request *models.Chat
 		}
 
 		// 添加详细的调试日志
-		headers := s.chatHeaders(xIsHuman)
+		headers := headerGen.GetChatHeaders(xIsHuman)
 		logrus.WithFields(logrus.Fields{
 			"url":            cursorAPIURL,
 			"x-is-human":     xIsHuman[:50] + "...", // 只显示前50个字符
@@ -113,13 +195,16 @@ func (s *CursorService) ChatCompletion(ctx context.Context, request *models.Chat
 			"attempt":        attempt,
 		}).Debug("Sending request to Cursor API")
 
-		resp, err := s.client.R().
+		resp, err := client.R().
 			SetContext(ctx).
 			SetHeaders(headers).
 			SetBody(jsonPayload).
 			DisableAutoReadResponse().
 			Post(cursorAPIURL)
 		if err != nil {
+			if releaseAccount != nil {
+				releaseAccount(false)
+			}
 			if attempt < maxRetries {
 				logrus.WithError(err).Warnf("Cursor request failed (attempt %d/%d), retrying...", attempt, maxRetries)
 				time.Sleep(time.Second * time.Duration(attempt))
@@ -143,14 +228,21 @@ func (s *CursorService) ChatCompletion(ctx context.Context, request *models.Chat
 
 			// 如果是 403 错误且还有重试机会,清除缓存并重试
 			if resp.StatusCode == http.StatusForbidden && attempt < maxRetries {
-				logrus.Warn("Received 403 Access Denied, refreshing browser fingerprint and clearing token cache...")
-
-				// 刷新浏览器指纹
-				s.headerGenerator.Refresh()
-				logrus.WithFields(logrus.Fields{
-					"platform":       s.headerGenerator.GetProfile().Platform,
-					"chrome_version": s.headerGenerator.GetProfile().ChromeVersion,
-				}).Debug("Refreshed browser fingerprint")
+				s.tokenProvider.recordFailure()
+				if releaseAccount != nil {
+					logrus.Warn("Received 403 Access Denied, cooling down account and retrying with another one...")
+					acc.ClearScriptCache()
+					releaseAccount(false)
+				} else {
+					logrus.Warn("Received 403 Access Denied, refreshing browser fingerprint and clearing token cache...")
+
+					// 刷新浏览器指纹
+					s.headerGenerator.Refresh()
+					logrus.WithFields(logrus.Fields{
+						"platform":       s.headerGenerator.GetProfile().Platform,
+						"chrome_version": s.headerGenerator.GetProfile().ChromeVersion,
+					}).Debug("Refreshed browser fingerprint")
+				}
 
 				// 清除 token 缓存
 				s.scriptMutex.Lock()
@@ -162,6 +254,10 @@ func (s *CursorService) ChatCompletion(ctx context.Context, request *models.Chat
 				continue
 			}
 
+			if releaseAccount != nil {
+				releaseAccount(false)
+			}
+
 			if strings.Contains(message, "Attention Required! | Cloudflare") {
 				message = "Cloudflare 403"
 			}
@@ -169,18 +265,50 @@ func (s *CursorService) ChatCompletion(ctx context.Context, request *models.Chat
 		}
 
 		// 成功,返回结果
+		s.tokenProvider.recordSuccess()
+		if releaseAccount != nil {
+			releaseAccount(true)
+		}
 		output := make(chan interface{}, 32)
-		go s.consumeSSE(ctx, resp.Response, output)
+		go s.consumeSSE(ctx, resp.Response, output, promptTokens, s.tokenizerForModel(request.Model))
 		return output, nil
 	}
 
 	return nil, fmt.Errorf("failed after %d attempts", maxRetries)
 }
 
-func (s *CursorService) consumeSSE(ctx context.Context, resp *http.Response, output chan interface{}) {
+// contentChunk is satisfied by any streamed event that exposes its text,
+// so consumeSSE can estimate completion tokens without depending on the
+// concrete SSE event type.
+type contentChunk interface {
+	GetStringContent() string
+}
+
+func (s *CursorService) consumeSSE(ctx context.Context, resp *http.Response, output chan interface{}, promptTokens int, tokenizer utils.Tokenizer) {
 	defer close(output)
 
-	if err := utils.ReadSSEStream(ctx, resp, output); err != nil {
+	// ReadSSEStream writes into tee instead of output directly so we can
+	// tally completion tokens as events pass through; forwardDone makes
+	// sure every forwarded event (and the token count) is accounted for
+	// before we emit the trailing usage event below.
+	completionTokens := 0
+	tee := make(chan interface{}, 32)
+	forwardDone := make(chan struct{})
+	go func() {
+		defer close(forwardDone)
+		for event := range tee {
+			if chunk, ok := event.(contentChunk); ok {
+				completionTokens += tokenizer.CountTokens(chunk.GetStringContent())
+			}
+			output <- event
+		}
+	}()
+
+	err := utils.ReadSSEStream(ctx, resp, tee)
+	close(tee)
+	<-forwardDone
+
+	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			return
 		}
@@ -190,19 +318,65 @@ func (s *CursorService) consumeSSE(ctx context.Context, resp *http.Response, out
 		default:
 			logrus.WithError(err).Warn("failed to push SSE error to channel")
 		}
+		return
+	}
+
+	usage := &models.Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+	}
+	select {
+	case output <- usage:
+	case <-ctx.Done():
+		logrus.Warn("failed to push usage summary to channel")
 	}
 }
 
-func (s *CursorService) fetchXIsHuman(ctx context.Context) (string, error) {
+// fetchXIsHuman fetches and executes the Cursor challenge script, using
+// acc's own client, headers and script cache when acc is non-nil (account
+// pool mode) so one account's challenge fetch never leaks its cookies,
+// fingerprint or cache into another's. A nil acc falls back to
+// CursorService's single shared client/cache, for deployments that don't
+// configure an account pool.
+func (s *CursorService) fetchXIsHuman(ctx context.Context, acc *accounts.Account) (string, error) {
+	scriptClient := s.client
+	scriptHeaders := s.scriptHeaders()
+	getCached := func() (string, bool) {
+		s.scriptMutex.RLock()
+		defer s.scriptMutex.RUnlock()
+		if s.scriptCache == "" || time.Since(s.scriptCacheTime) >= 1*time.Minute {
+			return "", false
+		}
+		return s.scriptCache, true
+	}
+	setCache := func(body string) {
+		s.scriptMutex.Lock()
+		s.scriptCache = body
+		s.scriptCacheTime = time.Now()
+		s.scriptMutex.Unlock()
+	}
+	clearCache := func() {
+		s.scriptMutex.Lock()
+		s.scriptCache = ""
+		s.scriptCacheTime = time.Time{}
+		s.scriptMutex.Unlock()
+	}
+
+	if acc != nil {
+		scriptClient = acc.Client
+		scriptHeaders = acc.Headers.GetScriptHeaders()
+		getCached = func() (string, bool) { return acc.ScriptCache(1 * time.Minute) }
+		setCache = acc.SetScriptCache
+		clearCache = acc.ClearScriptCache
+	}
+
 	// 检查缓存
-	s.scriptMutex.RLock()
-	cached := s.scriptCache
-	lastFetch := s.scriptCacheTime
-	s.scriptMutex.RUnlock()
+	cached, hasCached := getCached()
 
 	var scriptBody string
 	// 缓存有效期缩短到1分钟,避免 token 过期
-	if cached != "" && time.Since(lastFetch) < 1*time.Minute {
+	if hasCached {
 		scriptBody = cached
 	} else {
 		// 如果 SCRIPT_URL 为空或无法访问，使用空字符串（降级方案）
@@ -210,9 +384,9 @@ func (s *CursorService) fetchXIsHuman(ctx context.Context) (string, error) {
 			logrus.Warn("SCRIPT_URL is empty, using fallback mode")
 			scriptBody = ""
 		} else {
-			resp, err := s.client.R().
+			resp, err := scriptClient.R().
 				SetContext(ctx).
-				SetHeaders(s.scriptHeaders()).
+				SetHeaders(scriptHeaders).
 				Get(s.config.ScriptURL)
 
 			if err != nil {
@@ -236,22 +410,16 @@ func (s *CursorService) fetchXIsHuman(ctx context.Context) (string, error) {
 			} else {
 				scriptBody = string(resp.Bytes())
 				// 更新缓存
-				s.scriptMutex.Lock()
-				s.scriptCache = scriptBody
-				s.scriptCacheTime = time.Now()
-				s.scriptMutex.Unlock()
+				setCache(scriptBody)
 			}
 		}
 	}
 
 	compiled := s.prepareJS(scriptBody)
-	value, err := utils.RunJS(compiled)
+	value, err := s.jsPool.Eval(ctx, compiled)
 	if err != nil {
 		// JS 执行失败时清除缓存
-		s.scriptMutex.Lock()
-		s.scriptCache = ""
-		s.scriptCacheTime = time.Time{}
-		s.scriptMutex.Unlock()
+		clearCache()
 		return "", fmt.Errorf("failed to execute JS: %w", err)
 	}
 
@@ -274,53 +442,103 @@ func (s *CursorService) prepareJS(cursorJS string) string {
 	return mainScript
 }
 
-func (s *CursorService) truncateMessages(messages []models.Message) []models.Message {
-	if len(messages) == 0 || s.config.MaxInputLength <= 0 {
-		return messages
+// encodingForModel returns the BPE encoding name configured for model,
+// falling back to the tokenizer package's own default when the model is
+// unknown or has none configured.
+func (s *CursorService) encodingForModel(model string) string {
+	if cfg, exists := models.GetModelConfig(model); exists && cfg.Encoding != "" {
+		return cfg.Encoding
+	}
+	return ""
+}
+
+func (s *CursorService) tokenizerForModel(model string) utils.Tokenizer {
+	return utils.NewTokenizer(s.encodingForModel(model))
+}
+
+// truncateMessages trims messages to fit within MaxInputLength tokens
+// (reserving ReservedCompletionTokens for the reply), and returns the
+// resulting messages along with their estimated prompt token count.
+//
+// The leading system message is always kept. The remaining messages are
+// considered newest first, and each is kept whole if it still fits the
+// remaining budget; one that doesn't fit is skipped and older ones are
+// still considered, so a message is never split across the boundary. The
+// one exception is the single most recent message when it alone exceeds
+// the entire remaining budget - rather than dropping it, its content is
+// middle-out summarized (the middle is cut and replaced with "[...]") and
+// no older message is kept. If the remaining budget can't hold even the
+// "[...]" marker (e.g. the system message alone uses it up), that message
+// is kept with empty content.
+func (s *CursorService) truncateMessages(messages []models.Message, model string) ([]models.Message, int) {
+	if len(messages) == 0 {
+		return messages, 0
 	}
 
-	maxLength := s.config.MaxInputLength
+	tokenizer := s.tokenizerForModel(model)
+
+	tokenCounts := make([]int, len(messages))
 	total := 0
-	for _, msg := range messages {
-		total += len(msg.GetStringContent())
+	for i, msg := range messages {
+		tokenCounts[i] = tokenizer.CountTokens(msg.GetStringContent())
+		total += tokenCounts[i]
+	}
+
+	maxTokens := s.config.MaxInputLength - s.config.ReservedCompletionTokens
+	if maxTokens < 0 {
+		maxTokens = 0
 	}
 
-	if total <= maxLength {
-		return messages
+	if s.config.MaxInputLength <= 0 || total <= maxTokens {
+		return messages, total
 	}
 
 	var result []models.Message
 	startIdx := 0
+	remaining := maxTokens
 
+	// 始终保留开头的 system message
 	if strings.EqualFold(messages[0].Role, "system") {
 		result = append(result, messages[0])
-		maxLength -= len(messages[0].GetStringContent())
-		if maxLength < 0 {
-			maxLength = 0
+		remaining -= tokenCounts[0]
+		if remaining < 0 {
+			remaining = 0
 		}
 		startIdx = 1
 	}
 
-	current := 0
 	collected := make([]models.Message, 0, len(messages)-startIdx)
-	for i := len(messages) - 1; i >= startIdx; i-- {
-		msg := messages[i]
-		msgLen := len(msg.GetStringContent())
-		if msgLen == 0 {
-			continue
-		}
-		if current+msgLen > maxLength {
-			continue
+	lastIdx := len(messages) - 1
+
+	if startIdx <= lastIdx {
+		if tokenCounts[lastIdx] > remaining {
+			// 最新的一条消息单独就超出剩余预算：摘要中间部分而不是整条丢弃
+			content := tokenizer.MiddleOutTruncate(messages[lastIdx].GetStringContent(), remaining)
+			collected = append(collected, messages[lastIdx].WithStringContent(content))
+			remaining = 0
+		} else {
+			for i := lastIdx; i >= startIdx; i-- {
+				if tokenCounts[i] > remaining {
+					continue
+				}
+				collected = append(collected, messages[i])
+				remaining -= tokenCounts[i]
+			}
 		}
-		collected = append(collected, msg)
-		current += msgLen
 	}
 
 	for i, j := 0, len(collected)-1; i < j; i, j = i+1, j-1 {
 		collected[i], collected[j] = collected[j], collected[i]
 	}
 
-	return append(result, collected...)
+	result = append(result, collected...)
+
+	promptTokens := 0
+	for _, msg := range result {
+		promptTokens += tokenizer.CountTokens(msg.GetStringContent())
+	}
+
+	return result, promptTokens
 }
 
 func (s *CursorService) chatHeaders(xIsHuman string) map[string]string {
@@ -0,0 +1,19 @@
+//go:build !qjs
+
+package services
+
+import (
+	"cursor2api-go/config"
+	"cursor2api-go/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newQuickJSBackend is the default-build stand-in used when the binary
+// wasn't compiled with `-tags qjs`. utils.NewQuickJSRuntime is cgo-only
+// and not part of this build, so selecting "quickjs" here falls back to
+// goja instead of failing to compile.
+func newQuickJSBackend(cfg *config.Config) utils.JSRuntime {
+	logrus.Warn("jsRuntime=quickjs requires a binary built with `-tags qjs`; falling back to goja")
+	return utils.NewGojaRuntime(cfg.JSMemoryLimitBytes)
+}
@@ -0,0 +1,14 @@
+//go:build qjs
+
+package services
+
+import (
+	"cursor2api-go/config"
+	"cursor2api-go/utils"
+)
+
+// newQuickJSBackend builds the cgo-backed QuickJS runtime. Only compiled
+// in when the "qjs" build tag is set.
+func newQuickJSBackend(cfg *config.Config) utils.JSRuntime {
+	return utils.NewQuickJSRuntime(cfg.JSMemoryLimitBytes)
+}
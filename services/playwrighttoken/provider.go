@@ -0,0 +1,194 @@
+// Package playwrighttoken generates Cursor's `x-is-human` challenge token
+// by running the real challenge script in a headless Chromium instead of
+// a JS interpreter, trading per-token latency for resilience against
+// Cursor changing the script in ways a JS VM emulation layer can't follow.
+package playwrighttoken
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cursor2api-go/services/accounts"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Config controls the browser fingerprint presented to Cursor and how
+// the warm context pool is sized.
+type Config struct {
+	UserAgent             string
+	UnmaskedVendorWebGL   string
+	UnmaskedRendererWebGL string
+	PoolSize              int
+	NavigationTimeout     time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.PoolSize <= 0 {
+		c.PoolSize = 2
+	}
+	if c.NavigationTimeout <= 0 {
+		c.NavigationTimeout = 15 * time.Second
+	}
+	return c
+}
+
+// webglSpoofScript overrides WEBGL_debug_renderer_info before any page
+// script runs, so the challenge sees the configured vendor/renderer
+// instead of whatever the real headless GPU reports.
+const webglSpoofScript = `
+(() => {
+  const vendor = %q, renderer = %q;
+  const proto = WebGLRenderingContext.prototype;
+  const orig = proto.getParameter;
+  proto.getParameter = function(param) {
+    if (param === 37445) return vendor;
+    if (param === 37446) return renderer;
+    return orig.call(this, param);
+  };
+})();`
+
+// Provider is a TokenProvider backed by a pool of warm Chromium contexts.
+type Provider struct {
+	cfg Config
+	pw  *playwright.Playwright
+	bro playwright.Browser
+
+	mu   sync.Mutex
+	pool []playwright.BrowserContext
+}
+
+// New launches headless Chromium and prepares a warm context pool.
+func New(cfg Config) (*Provider, error) {
+	cfg = cfg.withDefaults()
+
+	pw, err := playwright.Run()
+	if err != nil {
+		return nil, fmt.Errorf("playwrighttoken: failed to start playwright: %w", err)
+	}
+
+	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+		Headless: playwright.Bool(true),
+	})
+	if err != nil {
+		pw.Stop()
+		return nil, fmt.Errorf("playwrighttoken: failed to launch chromium: %w", err)
+	}
+
+	p := &Provider{cfg: cfg, pw: pw, bro: browser}
+	for i := 0; i < cfg.PoolSize; i++ {
+		ctx, err := p.newContext()
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.pool = append(p.pool, ctx)
+	}
+	return p, nil
+}
+
+func (p *Provider) newContext() (playwright.BrowserContext, error) {
+	ctx, err := p.bro.NewContext(playwright.BrowserNewContextOptions{
+		UserAgent: playwright.String(p.cfg.UserAgent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("playwrighttoken: failed to create browser context: %w", err)
+	}
+	if err := ctx.AddInitScript(playwright.Script{
+		Content: playwright.String(fmt.Sprintf(webglSpoofScript, p.cfg.UnmaskedVendorWebGL, p.cfg.UnmaskedRendererWebGL)),
+	}); err != nil {
+		ctx.Close()
+		return nil, fmt.Errorf("playwrighttoken: failed to install webgl spoof: %w", err)
+	}
+	return ctx, nil
+}
+
+func (p *Provider) acquireContext() (playwright.BrowserContext, error) {
+	p.mu.Lock()
+	if n := len(p.pool); n > 0 {
+		ctx := p.pool[n-1]
+		p.pool = p.pool[:n-1]
+		p.mu.Unlock()
+		return ctx, nil
+	}
+	p.mu.Unlock()
+	return p.newContext()
+}
+
+func (p *Provider) releaseContext(ctx playwright.BrowserContext) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pool) >= p.cfg.PoolSize {
+		ctx.Close()
+		return
+	}
+	p.pool = append(p.pool, ctx)
+}
+
+// Token implements services.TokenProvider. It navigates to cursor.com,
+// waits for the challenge script to populate `window.__xIsHuman`, and
+// returns it. Playwright tokens aren't account-scoped, so acc is ignored.
+func (p *Provider) Token(ctx context.Context, acc *accounts.Account) (string, error) {
+	browserCtx, err := p.acquireContext()
+	if err != nil {
+		return "", err
+	}
+	defer p.releaseContext(browserCtx)
+
+	page, err := browserCtx.NewPage()
+	if err != nil {
+		return "", fmt.Errorf("playwrighttoken: failed to open page: %w", err)
+	}
+	defer page.Close()
+
+	timeout := float64(p.cfg.NavigationTimeout.Milliseconds())
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < p.cfg.NavigationTimeout {
+			timeout = float64(remaining.Milliseconds())
+		}
+	}
+
+	if _, err := page.Goto("https://cursor.com", playwright.PageGotoOptions{Timeout: playwright.Float(timeout)}); err != nil {
+		return "", fmt.Errorf("playwrighttoken: navigation failed: %w", err)
+	}
+
+	value, err := page.WaitForFunction(`() => window.__xIsHuman || null`, nil, playwright.PageWaitForFunctionOptions{
+		Timeout: playwright.Float(timeout),
+	})
+	if err != nil {
+		return "", fmt.Errorf("playwrighttoken: challenge did not populate x-is-human: %w", err)
+	}
+
+	token, err := value.JSONValue()
+	if err != nil {
+		return "", fmt.Errorf("playwrighttoken: failed to read x-is-human: %w", err)
+	}
+	str, _ := token.(string)
+	if str == "" {
+		return "", fmt.Errorf("playwrighttoken: empty x-is-human token")
+	}
+	return str, nil
+}
+
+// Name implements services.TokenProvider.
+func (p *Provider) Name() string { return "playwright" }
+
+// Close shuts down the browser and the Playwright driver.
+func (p *Provider) Close() error {
+	p.mu.Lock()
+	for _, ctx := range p.pool {
+		ctx.Close()
+	}
+	p.pool = nil
+	p.mu.Unlock()
+
+	if p.bro != nil {
+		p.bro.Close()
+	}
+	if p.pw != nil {
+		return p.pw.Stop()
+	}
+	return nil
+}
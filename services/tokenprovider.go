@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"cursor2api-go/services/accounts"
+)
+
+// TokenProvider produces the `x-is-human` header value Cursor expects on
+// chat requests. `jsvm` (backed by the existing isolate pool) is the
+// default; `playwright` trades startup cost for resilience against
+// Cursor changing their challenge script in ways the JS VM can't follow.
+// acc is the account acquired for the current attempt (nil when no
+// account pool is configured); implementations that aren't account-scoped
+// (e.g. playwright) are free to ignore it.
+type TokenProvider interface {
+	Token(ctx context.Context, acc *accounts.Account) (string, error)
+	Name() string
+}
+
+// jsvmTokenProvider is a thin adapter over CursorService's existing
+// challenge-script pipeline (fetch + cache + JS isolate eval), so it can
+// be selected through the same TokenProvider interface as playwright.
+type jsvmTokenProvider struct {
+	svc *CursorService
+}
+
+func (p *jsvmTokenProvider) Token(ctx context.Context, acc *accounts.Account) (string, error) {
+	return p.svc.fetchXIsHuman(ctx, acc)
+}
+
+func (p *jsvmTokenProvider) Name() string { return "jsvm" }
+
+// failoverTokenProvider tries primary first and switches to fallback once
+// recordFailure has been called `threshold` times with no recordSuccess in
+// between. The next recordSuccess, after a successful request served by
+// either provider, clears the streak and switches back to primary.
+type failoverTokenProvider struct {
+	primary   TokenProvider
+	fallback  TokenProvider
+	threshold int
+
+	mu          sync.Mutex
+	failures    int
+	useFallback bool
+}
+
+// newFailoverTokenProvider builds a provider that fails over from primary
+// to fallback after threshold consecutive failures. If fallback is nil,
+// it behaves exactly like primary.
+func newFailoverTokenProvider(primary, fallback TokenProvider, threshold int) *failoverTokenProvider {
+	if threshold <= 0 {
+		threshold = 3
+	}
+	return &failoverTokenProvider{primary: primary, fallback: fallback, threshold: threshold}
+}
+
+func (p *failoverTokenProvider) active() TokenProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.useFallback && p.fallback != nil {
+		return p.fallback
+	}
+	return p.primary
+}
+
+func (p *failoverTokenProvider) Token(ctx context.Context, acc *accounts.Account) (string, error) {
+	return p.active().Token(ctx, acc)
+}
+
+func (p *failoverTokenProvider) Name() string {
+	return p.active().Name()
+}
+
+// recordFailure is called after a 403 so repeated Cursor rejections can
+// trigger failover onto the fallback provider.
+func (p *failoverTokenProvider) recordFailure() {
+	if p.fallback == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures++
+	if p.failures >= p.threshold {
+		p.useFallback = true
+	}
+}
+
+// recordSuccess clears the failure streak and switches back to primary.
+func (p *failoverTokenProvider) recordSuccess() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures = 0
+	p.useFallback = false
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Isolate is a single, reusable JS execution context. It must not be used
+// by more than one goroutine at a time. Request-scoped values (script src,
+// UA, WebGL fingerprint, ...) aren't injected as JS globals here - they're
+// baked into src as literal text by CursorService.prepareJS before Eval is
+// called, since the challenge script never references them as identifiers.
+type Isolate interface {
+	// Eval compiles and runs src, returning its string result.
+	Eval(ctx context.Context, src string) (string, error)
+	// Reset clears any state left over from a previous Eval so the isolate
+	// can be safely returned to the pool.
+	Reset()
+}
+
+// JSRuntime is the pluggable backend used to create isolates. Implementations
+// are expected to be safe for concurrent use; the isolates they hand out are
+// not.
+type JSRuntime interface {
+	// NewIsolate creates a fresh, ready-to-use isolate.
+	NewIsolate() (Isolate, error)
+	// Name identifies the backend, e.g. "goja" or "quickjs".
+	Name() string
+	// Close releases any resources held by the runtime itself.
+	Close() error
+}
+
+// IsolatePoolConfig controls pool sizing and the limits applied to every
+// isolate it hands out.
+type IsolatePoolConfig struct {
+	// MaxIsolates bounds how many idle isolates the pool will keep warm.
+	// Acquire never blocks: with no idle isolate it creates a new one, and
+	// a released isolate is discarded only if MaxIsolates are already idle.
+	MaxIsolates int
+	// EvalTimeout is the hard wall-clock limit for a single Eval call.
+	EvalTimeout time.Duration
+	// MemoryLimitBytes is not read by the pool; memory limits are set on
+	// the JSRuntime when it is constructed (see NewGojaRuntime and
+	// NewQuickJSRuntime), so this field has no effect.
+	MemoryLimitBytes uint64
+	// MaxIdleTime is how long an isolate may sit idle in the pool before
+	// it's evicted and discarded on the next Acquire.
+	MaxIdleTime time.Duration
+}
+
+func (c IsolatePoolConfig) withDefaults() IsolatePoolConfig {
+	if c.MaxIsolates <= 0 {
+		c.MaxIsolates = 8
+	}
+	if c.EvalTimeout <= 0 {
+		c.EvalTimeout = 5 * time.Second
+	}
+	if c.MaxIdleTime <= 0 {
+		c.MaxIdleTime = 2 * time.Minute
+	}
+	return c
+}
+
+type pooledIsolate struct {
+	Isolate
+	idleSince time.Time
+}
+
+// IsolatePool hands out short-lived isolates backed by a JSRuntime,
+// evicting ones that have been idle too long so a stalled or leaking
+// isolate can't pin down memory indefinitely.
+type IsolatePool struct {
+	runtime JSRuntime
+	cfg     IsolatePoolConfig
+
+	mu   sync.Mutex
+	idle []*pooledIsolate
+}
+
+// NewIsolatePool creates a pool of isolates backed by runtime.
+func NewIsolatePool(runtime JSRuntime, cfg IsolatePoolConfig) *IsolatePool {
+	return &IsolatePool{
+		runtime: runtime,
+		cfg:     cfg.withDefaults(),
+	}
+}
+
+// Acquire returns a ready isolate, reusing a warm one when available and
+// falling back to creating a new one otherwise. The caller must call
+// release() exactly once when done.
+func (p *IsolatePool) Acquire() (Isolate, func(), error) {
+	p.mu.Lock()
+	p.evictExpiredLocked()
+	var iso *pooledIsolate
+	if n := len(p.idle); n > 0 {
+		iso = p.idle[n-1]
+		p.idle = p.idle[:n-1]
+	}
+	p.mu.Unlock()
+
+	if iso == nil {
+		raw, err := p.runtime.NewIsolate()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create %s isolate: %w", p.runtime.Name(), err)
+		}
+		iso = &pooledIsolate{Isolate: raw}
+	}
+
+	released := false
+	release := func() {
+		if released {
+			return
+		}
+		released = true
+		iso.Reset()
+		iso.idleSince = time.Now()
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if len(p.idle) >= p.cfg.MaxIsolates {
+			return
+		}
+		p.idle = append(p.idle, iso)
+	}
+
+	return iso, release, nil
+}
+
+// evictExpiredLocked drops idle isolates that have outlived MaxIdleTime.
+// Callers must hold p.mu.
+func (p *IsolatePool) evictExpiredLocked() {
+	cutoff := time.Now().Add(-p.cfg.MaxIdleTime)
+	fresh := p.idle[:0]
+	for _, iso := range p.idle {
+		if iso.idleSince.Before(cutoff) {
+			continue
+		}
+		fresh = append(fresh, iso)
+	}
+	p.idle = fresh
+}
+
+// Eval acquires an isolate, runs src under the pool's configured timeout
+// and returns the isolate to the pool (or discards it, if the caller's
+// context was already cancelled).
+func (p *IsolatePool) Eval(ctx context.Context, src string) (string, error) {
+	iso, release, err := p.Acquire()
+	if err != nil {
+		return "", err
+	}
+
+	evalCtx, cancel := context.WithTimeout(ctx, p.cfg.EvalTimeout)
+	defer cancel()
+
+	value, err := iso.Eval(evalCtx, src)
+	if ctx.Err() == nil {
+		release()
+	}
+	return value, err
+}
+
+// Close shuts down the underlying runtime. It does not wait for isolates
+// currently on loan.
+func (p *IsolatePool) Close() error {
+	return p.runtime.Close()
+}
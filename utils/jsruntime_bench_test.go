@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+const benchScript = `(function() { var s = "x"; for (var i = 0; i < 100; i++) { s += i; } return s; })()`
+
+func BenchmarkGojaIsolate_ColdStart(b *testing.B) {
+	runtime := NewGojaRuntime(0)
+	for i := 0; i < b.N; i++ {
+		iso, err := runtime.NewIsolate()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := iso.Eval(context.Background(), benchScript); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGojaIsolate_Pooled(b *testing.B) {
+	pool := NewIsolatePool(NewGojaRuntime(0), IsolatePoolConfig{MaxIsolates: 16})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := pool.Eval(context.Background(), benchScript); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIsolatePool_AcquireRelease(b *testing.B) {
+	pool := NewIsolatePool(NewGojaRuntime(0), IsolatePoolConfig{MaxIsolates: 16})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, release, err := pool.Acquire()
+		if err != nil {
+			b.Fatal(err)
+		}
+		release()
+	}
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dop251/goja"
+	"github.com/sirupsen/logrus"
+)
+
+// GojaRuntime is the default JSRuntime backend. It's pure Go, requires no
+// cgo, and is what RunJS used before isolates existed.
+//
+// goja has no API for limiting a *goja.Runtime's heap usage, so unlike the
+// QuickJS backend, memoryLimitBytes is accepted for config-shape parity but
+// not enforced here; a wall-clock timeout (see Eval) is the only hard stop
+// on a runaway script.
+type GojaRuntime struct {
+	memoryLimitBytes uint64
+}
+
+// NewGojaRuntime creates the default backend. memoryLimitBytes is currently
+// unused (see GojaRuntime's doc comment) but kept so callers can switch
+// between backends without changing the config shape.
+func NewGojaRuntime(memoryLimitBytes uint64) *GojaRuntime {
+	if memoryLimitBytes > 0 {
+		logrus.Warn("jsMemoryLimitBytes is set but the goja backend cannot enforce a memory limit; only the eval timeout applies")
+	}
+	return &GojaRuntime{memoryLimitBytes: memoryLimitBytes}
+}
+
+// NewIsolate implements JSRuntime.
+func (r *GojaRuntime) NewIsolate() (Isolate, error) {
+	return &gojaIsolate{vm: goja.New()}, nil
+}
+
+// Name implements JSRuntime.
+func (r *GojaRuntime) Name() string { return "goja" }
+
+// Close implements JSRuntime. The goja backend holds no shared resources.
+func (r *GojaRuntime) Close() error { return nil }
+
+type gojaIsolate struct {
+	vm *goja.Runtime
+}
+
+func (i *gojaIsolate) Eval(ctx context.Context, src string) (string, error) {
+	vm := i.vm
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			vm.Interrupt(ctx.Err())
+		case <-done:
+		}
+	}()
+	defer close(done)
+
+	value, err := vm.RunString(src)
+	if err != nil {
+		return "", fmt.Errorf("js execution failed: %w", err)
+	}
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return "", nil
+	}
+	return value.String(), nil
+}
+
+func (i *gojaIsolate) Reset() {
+	i.vm = goja.New()
+}
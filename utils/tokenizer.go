@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultEncoding is used for models we don't have an explicit mapping
+// for; cl100k_base is close enough for rough budgeting purposes.
+const defaultEncoding = "cl100k_base"
+
+// Tokenizer estimates how many tokens a model would spend on a given
+// string. Implementations are safe for concurrent use.
+type Tokenizer interface {
+	// CountTokens returns the estimated token count for text.
+	CountTokens(text string) int
+	// Encoding returns the BPE encoding name backing this tokenizer, e.g.
+	// "cl100k_base" or "o200k_base".
+	Encoding() string
+	// MiddleOutTruncate reduces text to at most maxTokens tokens by
+	// dropping tokens from the middle and splicing in "[...]", keeping the
+	// start and end of the original content intact. If text already fits,
+	// it's returned unchanged.
+	MiddleOutTruncate(text string, maxTokens int) string
+}
+
+type bpeTokenizer struct {
+	encoding string
+	bpe      *tiktoken.Tiktoken
+}
+
+func (t *bpeTokenizer) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return len(t.bpe.Encode(text, nil, nil))
+}
+
+func (t *bpeTokenizer) Encoding() string { return t.encoding }
+
+const middleOutMarker = "[...]"
+
+func (t *bpeTokenizer) MiddleOutTruncate(text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	tokens := t.bpe.Encode(text, nil, nil)
+	if len(tokens) <= maxTokens {
+		return text
+	}
+
+	markerTokens := len(t.bpe.Encode(middleOutMarker, nil, nil))
+	budget := maxTokens - markerTokens
+	if budget < 0 {
+		return ""
+	}
+
+	head := (budget + 1) / 2
+	tail := budget - head
+
+	// Token boundaries don't line up with rune boundaries: cl100k/o200k can
+	// split a single multi-byte (e.g. CJK) character across two tokens, so
+	// decoding tokens[:head] or tokens[len(tokens)-tail:] in isolation can
+	// end in (or start with) a truncated UTF-8 sequence. Trim those partial
+	// runes off the cut edge rather than splicing in invalid bytes.
+	headText := trimTrailingPartialRune(t.bpe.Decode(tokens[:head]))
+	tailText := trimLeadingPartialRune(t.bpe.Decode(tokens[len(tokens)-tail:]))
+
+	return headText + middleOutMarker + tailText
+}
+
+// trimTrailingPartialRune drops a dangling incomplete UTF-8 sequence left at
+// the end of s by a cut that landed inside a multi-byte rune. Only the last
+// few bytes can ever be affected, so this only has to back off at most
+// utf8.UTFMax bytes.
+func trimTrailingPartialRune(s string) string {
+	for i := len(s); i > 0 && len(s)-i < utf8.UTFMax; i-- {
+		if utf8.ValidString(s[:i]) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// trimLeadingPartialRune drops a dangling incomplete UTF-8 sequence left at
+// the start of s by a cut that landed inside a multi-byte rune.
+func trimLeadingPartialRune(s string) string {
+	for i := 0; i < len(s) && i < utf8.UTFMax; i++ {
+		if utf8.ValidString(s[i:]) {
+			return s[i:]
+		}
+	}
+	return s
+}
+
+var (
+	tokenizerMu    sync.Mutex
+	tokenizerCache = map[string]Tokenizer{}
+)
+
+// NewTokenizer returns a Tokenizer for the given cl100k/o200k-style
+// encoding name, reusing a cached BPE instance across calls since loading
+// one is not free. An empty or unknown encoding falls back to cl100k_base.
+func NewTokenizer(encoding string) Tokenizer {
+	if encoding == "" {
+		encoding = defaultEncoding
+	}
+
+	tokenizerMu.Lock()
+	defer tokenizerMu.Unlock()
+
+	if tok, ok := tokenizerCache[encoding]; ok {
+		return tok
+	}
+
+	bpe, err := tiktoken.GetEncoding(encoding)
+	if err != nil {
+		requested := encoding
+		bpe, err = tiktoken.GetEncoding(defaultEncoding)
+		encoding = defaultEncoding
+		if err != nil {
+			// tiktoken-go has no bundled BPE ranks - it fetches them over
+			// HTTP on first use, so this is reachable on any host without
+			// egress to its blob storage, not just a malformed model
+			// config. Fall back to a byte-counting tokenizer rather than
+			// panic, but warn loudly since this silently degrades token
+			// accounting (worst for CJK/code) with no other signal.
+			logrus.WithField("requested_encoding", requested).
+				Warn("tiktoken: failed to load any BPE encoding, falling back to byte-length token estimation")
+			tok := &byteTokenizer{}
+			tokenizerCache[encoding] = tok
+			return tok
+		}
+	}
+
+	tok := &bpeTokenizer{encoding: encoding, bpe: bpe}
+	tokenizerCache[encoding] = tok
+	return tok
+}
+
+// byteTokenizer is the last-resort fallback when no BPE encoding can be
+// loaded at all. It approximates token count from UTF-8 byte length.
+type byteTokenizer struct{}
+
+func (byteTokenizer) CountTokens(text string) int { return (len(text) + 3) / 4 }
+func (byteTokenizer) Encoding() string            { return "byte-approx" }
+
+func (b byteTokenizer) MiddleOutTruncate(text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	if b.CountTokens(text) <= maxTokens {
+		return text
+	}
+
+	maxBytes := maxTokens * 4
+	markerLen := len(middleOutMarker)
+	budget := maxBytes - markerLen
+	if budget < 0 {
+		return ""
+	}
+
+	head := (budget + 1) / 2
+	tail := budget - head
+	// Same rune-boundary hazard as bpeTokenizer: head/tail are raw byte
+	// counts, so a naive slice can land inside a multi-byte rune.
+	headText := trimTrailingPartialRune(text[:head])
+	tailText := trimLeadingPartialRune(text[len(text)-tail:])
+	return headText + middleOutMarker + tailText
+}
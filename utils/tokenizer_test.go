@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNewTokenizer_UnknownEncodingFallsBackToDefault(t *testing.T) {
+	tok := NewTokenizer("not-a-real-encoding")
+	if tok.Encoding() != defaultEncoding {
+		t.Fatalf("expected fallback to %s, got %s", defaultEncoding, tok.Encoding())
+	}
+}
+
+func TestNewTokenizer_CachesByEncoding(t *testing.T) {
+	a := NewTokenizer("cl100k_base")
+	b := NewTokenizer("cl100k_base")
+	if a != b {
+		t.Fatal("expected NewTokenizer to reuse a cached instance for the same encoding")
+	}
+}
+
+func TestMiddleOutTruncate_ShortTextUnchanged(t *testing.T) {
+	tok := NewTokenizer("cl100k_base")
+	const text = "hello world"
+	if got := tok.MiddleOutTruncate(text, 100); got != text {
+		t.Fatalf("expected short text to pass through unchanged, got %q", got)
+	}
+}
+
+func TestMiddleOutTruncate_DropsMiddle(t *testing.T) {
+	tok := NewTokenizer("cl100k_base")
+	text := ""
+	for i := 0; i < 200; i++ {
+		text += "word "
+	}
+
+	truncated := tok.MiddleOutTruncate(text, 20)
+	if tok.CountTokens(truncated) > 20 {
+		t.Fatalf("expected truncated text to fit within 20 tokens, used %d", tok.CountTokens(truncated))
+	}
+	if truncated == text {
+		t.Fatal("expected long text to actually be truncated")
+	}
+}
+
+func TestMiddleOutTruncate_ValidUTF8AtCJKBoundary(t *testing.T) {
+	tok := NewTokenizer("cl100k_base")
+	// Repeated multi-byte CJK characters so the head/tail cut is likely to
+	// land inside one of the BPE tokens that spans multiple of them.
+	text := strings.Repeat("你好世界，今天天气怎么样。", 200)
+
+	truncated := tok.MiddleOutTruncate(text, 20)
+	if !utf8.ValidString(truncated) {
+		t.Fatalf("expected truncated text to be valid UTF-8, got %q", truncated)
+	}
+	if tok.CountTokens(truncated) > 20 {
+		t.Fatalf("expected truncated text to fit within 20 tokens, used %d", tok.CountTokens(truncated))
+	}
+}